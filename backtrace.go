@@ -0,0 +1,123 @@
+package rlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// backtraceLoc is one parsed "file.go:42" entry from SetBacktraceAt.
+type backtraceLoc struct {
+	file string
+	line int
+}
+
+// SetBacktraceAt installs the set of "file.go:42" locations that should
+// attach a goroutine stack dump to the record whenever Errorf is called
+// from that exact file and line, mirroring glog's -log_backtrace_at flag.
+// Locations may be passed as separate arguments or as comma-separated
+// strings (e.g. "foo.go:12,bar.go:34"). Calling SetBacktraceAt replaces any
+// previously installed set and invalidates the per-call-site cache.
+func (self *LogFormatter) SetBacktraceAt(locations ...string) error {
+	table, err := parseBacktraceAt(locations)
+	if err != nil {
+		return err
+	}
+
+	self.core.backtraceAt.Store(table)
+	self.core.btcache.Store(&sync.Map{})
+	return nil
+}
+
+// SetBacktraceAllGoroutines controls whether a stack dump captured via
+// SetBacktraceAt includes every goroutine (true) or only the one that
+// triggered the matching call (the default, false).
+func (self *LogFormatter) SetBacktraceAllGoroutines(all bool) {
+	var v int32
+	if all {
+		v = 1
+	}
+	atomic.StoreInt32(&self.core.backtraceAll, v)
+}
+
+// parseBacktraceAt parses the comma-separated "file.go:42" entries found in
+// locations into a table of backtraceLoc values.
+func parseBacktraceAt(locations []string) ([]backtraceLoc, error) {
+	var table []backtraceLoc
+
+	for _, arg := range locations {
+		for _, entry := range strings.Split(arg, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+
+			idx := strings.LastIndex(entry, ":")
+			if idx < 0 {
+				return nil, fmt.Errorf("rlog: invalid backtrace location %q", entry)
+			}
+
+			line, err := strconv.Atoi(entry[idx+1:])
+			if err != nil {
+				return nil, fmt.Errorf("rlog: invalid backtrace line in %q: %w", entry, err)
+			}
+
+			table = append(table, backtraceLoc{file: entry[:idx], line: line})
+		}
+	}
+
+	return table, nil
+}
+
+// matches reports whether file (as returned by runtime.Caller) satisfies loc,
+// matching against either the base filename or the full slash-form path.
+func (loc backtraceLoc) matches(file string, line int) bool {
+	if loc.line != line {
+		return false
+	}
+	return loc.file == filepath.Base(file) || loc.file == filepath.ToSlash(file)
+}
+
+// shouldBacktrace reports whether pc's file:line matches a configured
+// backtrace location, caching the verdict per call site so repeated calls
+// from the same line are an amortized O(1) sync.Map lookup.
+func (self *LogFormatter) shouldBacktrace(pc uintptr, file string, line int) bool {
+	cache, _ := self.core.btcache.Load().(*sync.Map)
+	if cache == nil {
+		return false
+	}
+
+	if v, found := cache.Load(pc); found {
+		return v.(bool)
+	}
+
+	table, _ := self.core.backtraceAt.Load().([]backtraceLoc)
+	matched := false
+	for _, loc := range table {
+		if loc.matches(file, line) {
+			matched = true
+			break
+		}
+	}
+
+	cache.Store(pc, matched)
+	return matched
+}
+
+// captureStack renders a goroutine stack dump, growing the scratch buffer
+// until it holds the full trace. When all is true every goroutine is
+// dumped, otherwise only the calling goroutine.
+func captureStack(all bool) string {
+	buf := make([]byte, 8192)
+	for {
+		n := runtime.Stack(buf, all)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, len(buf)*2)
+	}
+}
@@ -0,0 +1,62 @@
+package rlog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSetBacktraceAtAppendsStackOnMatch(t *testing.T) {
+	capture := NewCaptureSink()
+	logger := NewLogger(capture, true)
+
+	if err := logger.SetBacktraceAt("backtrace_test.go:17"); err != nil {
+		t.Fatalf("SetBacktraceAt: %v", err)
+	}
+
+	logger.Errorf("boom")
+	logger.Errorf("other")
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if !strings.Contains(entries[0].Message, "goroutine ") {
+		t.Errorf("matching call site missing backtrace: %q", entries[0].Message)
+	}
+	if strings.Contains(entries[1].Message, "goroutine ") {
+		t.Errorf("non-matching call site should not get a backtrace: %q", entries[1].Message)
+	}
+}
+
+func TestSetBacktraceAllGoroutinesDumpsEveryGoroutine(t *testing.T) {
+	capture := NewCaptureSink()
+	logger := NewLogger(capture, true)
+	logger.SetBacktraceAllGoroutines(true)
+
+	if err := logger.SetBacktraceAt("backtrace_test.go:53"); err != nil {
+		t.Fatalf("SetBacktraceAt: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	block := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-block
+	}()
+	defer func() {
+		close(block)
+		wg.Wait()
+	}()
+
+	logger.Errorf("boom")
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if strings.Count(entries[0].Message, "goroutine ") < 2 {
+		t.Errorf("expected a multi-goroutine dump, got: %q", entries[0].Message)
+	}
+}
@@ -17,13 +17,22 @@ type BufferedSink struct {
 type bufEntry struct {
 	when    time.Time
 	level   int
+	caller  string
 	message string
+	fields  []interface{}
 	ack     chan struct{} // used for flush level
 }
 
 // NewBufferedSink constructs a bounded queue backed sink. When the queue is full
 // producers block until there is space, trading off log retention for back pressure.
 func NewBufferedSink(size int, downstream LogSink) *BufferedSink {
+	return NewBufferedSinkWithClock(size, downstream, realClock{})
+}
+
+// NewBufferedSinkWithClock is like NewBufferedSink but lets tests inject a
+// fake Clock so the periodic flush ticker can be driven deterministically
+// instead of waiting on the wall clock.
+func NewBufferedSinkWithClock(size int, downstream LogSink, clock Clock) *BufferedSink {
 	s := &BufferedSink{
 		downstream: downstream,
 		queue:      make(chan bufEntry, size),
@@ -34,7 +43,7 @@ func NewBufferedSink(size int, downstream LogSink) *BufferedSink {
 	go func() {
 		defer s.wg.Done()
 
-		ticker := time.NewTicker(time.Millisecond * 333)
+		ticker := clock.NewTicker(time.Millisecond * 333)
 
 		defer ticker.Stop()
 
@@ -52,8 +61,8 @@ func NewBufferedSink(size int, downstream LogSink) *BufferedSink {
 					}
 					continue
 				}
-				s.downstream.Log(line.when, line.level, line.message)
-			case <-ticker.C:
+				deliverKV(s.downstream, line.when, line.level, line.caller, line.message, line.fields)
+			case <-ticker.C():
 				s.downstream.Flush()
 			}
 		}
@@ -74,6 +83,18 @@ func (self *BufferedSink) Log(when time.Time, level int, message string) {
 	}
 }
 
+// LogKV queues a structured record, preserving caller and fields for
+// downstream sinks that understand them (see LogSinkKV).
+func (self *BufferedSink) LogKV(when time.Time, level int, caller string, message string, fields []interface{}) {
+	self.queue <- bufEntry{
+		when:    when,
+		level:   level,
+		caller:  caller,
+		message: message,
+		fields:  fields,
+	}
+}
+
 func (self *BufferedSink) Close() {
 	self.queue <- bufEntry{level: closeLevel}
 	self.wg.Wait()
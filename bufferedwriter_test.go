@@ -0,0 +1,44 @@
+package rlog
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flushCountingSink wraps a LogSink and counts how many times Flush is
+// called, so a test can assert that a tick from a fake Ticker actually
+// reached the downstream sink.
+type flushCountingSink struct {
+	LogSink
+	flushes int32
+}
+
+func (self *flushCountingSink) Flush() {
+	atomic.AddInt32(&self.flushes, 1)
+}
+
+func TestBufferedSinkFlushesDownstreamOnTick(t *testing.T) {
+	downstream := &flushCountingSink{LogSink: NewCaptureSink()}
+	clock := &fakeClock{tickers: make(chan *fakeTicker, 1)}
+
+	sink := NewBufferedSinkWithClock(1, downstream, clock)
+	defer sink.Close()
+
+	var ticker *fakeTicker
+	select {
+	case ticker = <-clock.tickers:
+	case <-time.After(2 * time.Second):
+		t.Fatal("BufferedSink never created a ticker from the fake Clock")
+	}
+
+	ticker.c <- time.Unix(0, 0)
+
+	for i := 0; i < 1000; i++ {
+		if atomic.LoadInt32(&downstream.flushes) > 0 {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("tick on the fake Ticker never reached downstream.Flush")
+}
@@ -0,0 +1,59 @@
+package rlog
+
+import (
+	"sync"
+	"time"
+)
+
+// CaptureEntry is one record recorded by a CaptureSink.
+type CaptureEntry struct {
+	When    time.Time
+	Level   int
+	Caller  string
+	Message string
+	Fields  []interface{}
+}
+
+// CaptureSink records every logged record in memory instead of writing it
+// anywhere, so tests can assert on exactly what was logged. It is typically
+// installed via ReplaceDefaultLogger, with SaveState/RestoreState paired in a
+// defer to undo the swap.
+type CaptureSink struct {
+	mut     sync.Mutex
+	entries []CaptureEntry
+}
+
+// NewCaptureSink creates an empty CaptureSink.
+func NewCaptureSink() *CaptureSink {
+	return &CaptureSink{}
+}
+
+func (self *CaptureSink) IsEnabled(level int) bool {
+	return true
+}
+
+func (self *CaptureSink) Log(when time.Time, level int, message string) {
+	self.LogKV(when, level, "", message, nil)
+}
+
+func (self *CaptureSink) LogKV(when time.Time, level int, caller string, message string, fields []interface{}) {
+	self.mut.Lock()
+	self.entries = append(self.entries, CaptureEntry{When: when, Level: level, Caller: caller, Message: message, Fields: fields})
+	self.mut.Unlock()
+}
+
+func (self *CaptureSink) Close() {
+}
+
+func (self *CaptureSink) Flush() {
+}
+
+// Entries returns a snapshot of every record captured so far.
+func (self *CaptureSink) Entries() []CaptureEntry {
+	self.mut.Lock()
+	defer self.mut.Unlock()
+
+	out := make([]CaptureEntry, len(self.entries))
+	copy(out, self.entries)
+	return out
+}
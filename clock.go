@@ -0,0 +1,41 @@
+package rlog
+
+import "time"
+
+// Clock abstracts time access so tests can inject a deterministic source
+// instead of depending on the wall clock, letting them assert on record
+// timestamps and drive BufferedSink's periodic flush without sleeping.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the subset of time.Ticker that a Clock hands out, abstracted so
+// a fake Clock can deliver ticks under test control.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock is the default Clock, backed directly by the time package.
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (r realTicker) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r realTicker) Stop() {
+	r.t.Stop()
+}
@@ -0,0 +1,38 @@
+package rlog
+
+import "time"
+
+// fakeClock is a Clock whose Now() is set explicitly by a test, letting it
+// assert on deterministic timestamps instead of depending on time.Now.
+// NewTicker hands out a fakeTicker and also posts it to tickers, so a test
+// can pick up the instance a concurrently running pump goroutine created
+// and drive its channel directly, without racing on a plain field.
+type fakeClock struct {
+	now     time.Time
+	tickers chan *fakeTicker
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) NewTicker(d time.Duration) Ticker {
+	t := &fakeTicker{c: make(chan time.Time, 1)}
+	if f.tickers != nil {
+		f.tickers <- t
+	}
+	return t
+}
+
+// fakeTicker never fires on its own; tests that need ticks drive it by
+// sending on c directly.
+type fakeTicker struct {
+	c chan time.Time
+}
+
+func (f *fakeTicker) C() <-chan time.Time {
+	return f.c
+}
+
+func (f *fakeTicker) Stop() {
+}
@@ -0,0 +1,131 @@
+package rlog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"net"
+	"sync"
+	"time"
+)
+
+// ConnOptions controls the reconnection behavior of a ConnWriter.
+type ConnOptions struct {
+	// Reconnect re-dials the destination after a write error, retrying the
+	// failed message once against the new connection.
+	Reconnect bool
+	// ReconnectOnMsg closes the connection after every message, forcing a
+	// fresh dial on the next write. Useful for UDP-style one-shot shippers
+	// that should not hold a socket open between messages.
+	ReconnectOnMsg bool
+	// TLSConfig, when non-nil, wraps the dial in TLS using tls.Dial instead
+	// of net.Dial.
+	TLSConfig *tls.Config
+}
+
+// connWriter ships log records to a remote collector over a single
+// keep-alive connection, framing each record as a newline-delimited JSON
+// line so receivers like syslog-ng or a custom log server can parse it.
+type connWriter struct {
+	network  string
+	addr     string
+	minLevel int
+	opts     ConnOptions
+
+	mut  sync.Mutex
+	conn net.Conn
+}
+
+// NewConnWriter creates a sink that ships records to addr over network
+// (e.g. "tcp" or "udp"), filtering out records below minLevel. The
+// connection is dialed lazily on the first Log call. The returned sink is
+// wrapped in a BufferedSink so that a slow or unreachable remote endpoint
+// does not stall producers.
+func NewConnWriter(network, addr string, minLevel int, opts ConnOptions) LogSink {
+	cw := &connWriter{
+		network:  network,
+		addr:     addr,
+		minLevel: minLevel,
+		opts:     opts,
+	}
+	return NewBufferedSink(200, cw)
+}
+
+func (self *connWriter) IsEnabled(level int) bool {
+	return level >= self.minLevel
+}
+
+func (self *connWriter) Log(when time.Time, level int, message string) {
+	self.LogKV(when, level, "", message, nil)
+}
+
+func (self *connWriter) LogKV(when time.Time, level int, caller string, message string, fields []interface{}) {
+	if level < self.minLevel {
+		return
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	encodeJSONLine(buf, when, level, caller, message, fields)
+
+	self.write(buf.Bytes())
+
+	buf.Reset()
+	bufPool.Put(buf)
+}
+
+// write sends data over the keep-alive connection, lazily dialing on first
+// use and, when Reconnect is set, re-dialing once and retrying after a
+// write error. When ReconnectOnMsg is set the connection is closed after
+// every message so the next write dials fresh.
+func (self *connWriter) write(data []byte) {
+	self.mut.Lock()
+	defer self.mut.Unlock()
+
+	if self.conn == nil && !self.dialLocked() {
+		return
+	}
+
+	_, err := self.conn.Write(data)
+	if err != nil && self.opts.Reconnect {
+		_ = self.conn.Close()
+		self.conn = nil
+		if self.dialLocked() {
+			_, _ = self.conn.Write(data)
+		}
+	}
+
+	if self.opts.ReconnectOnMsg && self.conn != nil {
+		_ = self.conn.Close()
+		self.conn = nil
+	}
+}
+
+// dialLocked dials a fresh connection and stores it in self.conn. Callers
+// must hold self.mut.
+func (self *connWriter) dialLocked() bool {
+	var conn net.Conn
+	var err error
+
+	if self.opts.TLSConfig != nil {
+		conn, err = tls.Dial(self.network, self.addr, self.opts.TLSConfig)
+	} else {
+		conn, err = net.Dial(self.network, self.addr)
+	}
+	if err != nil {
+		return false
+	}
+
+	self.conn = conn
+	return true
+}
+
+func (self *connWriter) Close() {
+	self.mut.Lock()
+	if self.conn != nil {
+		_ = self.conn.Close()
+		self.conn = nil
+	}
+	self.mut.Unlock()
+}
+
+func (self *connWriter) Flush() {
+}
@@ -0,0 +1,162 @@
+package rlog
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// acceptOne starts a TCP loopback listener and returns its address along
+// with a channel that yields each accepted connection in order.
+func acceptOne(t *testing.T) (addr string, conns chan net.Conn) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+
+	conns = make(chan net.Conn, 8)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conns <- c
+		}
+	}()
+
+	return ln.Addr().String(), conns
+}
+
+func readLine(t *testing.T, conn net.Conn) string {
+	t.Helper()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("ReadString: %v", err)
+	}
+	return line
+}
+
+func TestConnWriterDialsLazilyAndWrites(t *testing.T) {
+	addr, conns := acceptOne(t)
+
+	cw := &connWriter{network: "tcp", addr: addr, minLevel: Debug}
+	cw.LogKV(time.Now(), Info, "", "hello", nil)
+	defer cw.Close()
+
+	conn := <-conns
+	line := readLine(t, conn)
+	if !strings.Contains(line, `"msg":"hello"`) {
+		t.Errorf("line = %q, want it to contain the message", line)
+	}
+}
+
+func TestConnWriterReconnectsAfterWriteError(t *testing.T) {
+	addr, conns := acceptOne(t)
+
+	cw := &connWriter{network: "tcp", addr: addr, minLevel: Debug, opts: ConnOptions{Reconnect: true}}
+	defer cw.Close()
+
+	cw.LogKV(time.Now(), Info, "", "first", nil)
+	first := <-conns
+	_ = readLine(t, first)
+
+	// Force the next write to fail immediately with a reset rather than a
+	// FIN, which the writer wouldn't observe until a second failed write.
+	if tcp, ok := first.(*net.TCPConn); ok {
+		_ = tcp.SetLinger(0)
+	}
+	_ = first.Close()
+
+	cw.LogKV(time.Now(), Info, "", "second", nil)
+
+	select {
+	case second := <-conns:
+		line := readLine(t, second)
+		if !strings.Contains(line, `"msg":"second"`) {
+			t.Errorf("line = %q, want the retried message", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("writer never redialed after a write error")
+	}
+}
+
+func TestConnWriterReconnectOnMsgClosesAfterEveryMessage(t *testing.T) {
+	addr, conns := acceptOne(t)
+
+	cw := &connWriter{network: "tcp", addr: addr, minLevel: Debug, opts: ConnOptions{ReconnectOnMsg: true}}
+	defer cw.Close()
+
+	cw.LogKV(time.Now(), Info, "", "one", nil)
+	cw.LogKV(time.Now(), Info, "", "two", nil)
+
+	first := <-conns
+	firstLine := readLine(t, first)
+	if !strings.Contains(firstLine, `"msg":"one"`) {
+		t.Fatalf("first line = %q, want the first message", firstLine)
+	}
+
+	select {
+	case second := <-conns:
+		secondLine := readLine(t, second)
+		if !strings.Contains(secondLine, `"msg":"two"`) {
+			t.Errorf("second line = %q, want the second message", secondLine)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ReconnectOnMsg did not force a fresh dial for the second message")
+	}
+}
+
+func TestConnWriterCloseReleasesConnection(t *testing.T) {
+	addr, conns := acceptOne(t)
+
+	cw := &connWriter{network: "tcp", addr: addr, minLevel: Debug}
+	cw.LogKV(time.Now(), Info, "", "hello", nil)
+
+	conn := <-conns
+	_ = readLine(t, conn)
+
+	cw.Close()
+
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err == nil {
+		t.Errorf("expected the server side to observe EOF after Close, got no error")
+	}
+}
+
+// TestNewConnWriterDrainsQueuedWritesBeforeClosing exercises the public
+// NewConnWriter entry point, which wraps connWriter in a BufferedSink:
+// messages queued before Close is called must still reach the remote
+// socket before the connection goes away.
+func TestNewConnWriterDrainsQueuedWritesBeforeClosing(t *testing.T) {
+	addr, conns := acceptOne(t)
+
+	sink := NewConnWriter("tcp", addr, Debug, ConnOptions{})
+	sink.Log(time.Now(), Info, "one")
+	sink.Log(time.Now(), Info, "two")
+	sink.Log(time.Now(), Info, "three")
+	sink.Close()
+
+	conn := <-conns
+	r := bufio.NewReader(conn)
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	for _, want := range []string{"one", "two", "three"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("ReadString: %v", err)
+		}
+		if !strings.Contains(line, `"msg":"`+want+`"`) {
+			t.Errorf("line = %q, want message %q", line, want)
+		}
+	}
+}
+
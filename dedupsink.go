@@ -0,0 +1,105 @@
+package rlog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DedupSink suppresses identical (level, message) pairs seen within window,
+// emitting a single coalesced "... (repeated N times)" line instead once the
+// window closes or a different message arrives. This keeps a hot loop that
+// logs the same failure repeatedly from flooding the downstream sink.
+type DedupSink struct {
+	downstream LogSink
+	window     time.Duration
+
+	mut     sync.Mutex
+	pending bool
+	when    time.Time
+	level   int
+	caller  string
+	message string
+	fields  []interface{}
+	count   int
+	timer   *time.Timer
+}
+
+// NewDedupSink wraps downstream so that repeats of the same (level, message)
+// within window are coalesced into a single record.
+func NewDedupSink(downstream LogSink, window time.Duration) *DedupSink {
+	return &DedupSink{downstream: downstream, window: window}
+}
+
+func (self *DedupSink) IsEnabled(level int) bool {
+	return self.downstream.IsEnabled(level)
+}
+
+func (self *DedupSink) Log(when time.Time, level int, message string) {
+	self.LogKV(when, level, "", message, nil)
+}
+
+func (self *DedupSink) LogKV(when time.Time, level int, caller string, message string, fields []interface{}) {
+	self.mut.Lock()
+	defer self.mut.Unlock()
+
+	if self.pending && level == self.level && message == self.message {
+		self.count++
+		return
+	}
+
+	self.flushLocked()
+
+	self.pending = true
+	self.when, self.level, self.caller, self.message, self.fields = when, level, caller, message, fields
+	self.count = 1
+
+	if self.timer == nil {
+		self.timer = time.AfterFunc(self.window, self.onExpire)
+	} else {
+		self.timer.Reset(self.window)
+	}
+}
+
+// onExpire fires when window elapses without a new message arriving,
+// flushing whatever is pending.
+func (self *DedupSink) onExpire() {
+	self.mut.Lock()
+	self.flushLocked()
+	self.mut.Unlock()
+}
+
+// flushLocked emits the pending record, coalescing a repeat count into the
+// message when it was seen more than once. Callers must hold self.mut.
+func (self *DedupSink) flushLocked() {
+	if !self.pending {
+		return
+	}
+
+	message := self.message
+	if self.count > 1 {
+		message = fmt.Sprintf("%s ... (repeated %d times)", message, self.count)
+	}
+
+	deliverKV(self.downstream, self.when, self.level, self.caller, message, self.fields)
+	self.pending = false
+}
+
+func (self *DedupSink) Close() {
+	self.mut.Lock()
+	if self.timer != nil {
+		self.timer.Stop()
+	}
+	self.flushLocked()
+	self.mut.Unlock()
+
+	self.downstream.Close()
+}
+
+func (self *DedupSink) Flush() {
+	self.mut.Lock()
+	self.flushLocked()
+	self.mut.Unlock()
+
+	self.downstream.Flush()
+}
@@ -0,0 +1,42 @@
+package rlog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDedupSinkCoalescesRepeatsOnFlush(t *testing.T) {
+	capture := NewCaptureSink()
+	sink := NewDedupSink(capture, time.Hour)
+
+	sink.Log(time.Time{}, Error, "disk full")
+	sink.Log(time.Time{}, Error, "disk full")
+	sink.Log(time.Time{}, Error, "disk full")
+	sink.Flush()
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if !strings.Contains(entries[0].Message, "repeated 3 times") {
+		t.Errorf("message = %q, want it to mention the repeat count", entries[0].Message)
+	}
+}
+
+func TestDedupSinkPassesThroughDistinctMessages(t *testing.T) {
+	capture := NewCaptureSink()
+	sink := NewDedupSink(capture, time.Hour)
+
+	sink.Log(time.Time{}, Error, "disk full")
+	sink.Log(time.Time{}, Error, "network down")
+	sink.Flush()
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if entries[0].Message != "disk full" || entries[1].Message != "network down" {
+		t.Errorf("unexpected messages: %q, %q", entries[0].Message, entries[1].Message)
+	}
+}
@@ -55,6 +55,35 @@ func Fatalf(format string, v ...interface{}) {
 	os.Exit(1)
 }
 
+// V reports whether level is enabled for the calling file/line on the default
+// logger, see LogFormatter.V.
+func V(level int) Verbose {
+	return GetDefaultLogger().V(level)
+}
+
+// SetVerbosity sets the default logger's fallback verbosity level used by V.
+func SetVerbosity(level int) {
+	GetDefaultLogger().SetVerbosity(level)
+}
+
+// SetVModule installs per-file verbosity overrides on the default logger,
+// see LogFormatter.SetVModule.
+func SetVModule(spec string) error {
+	return GetDefaultLogger().SetVModule(spec)
+}
+
+// SetBacktraceAt installs the default logger's backtrace-on-error locations,
+// see LogFormatter.SetBacktraceAt.
+func SetBacktraceAt(locations ...string) error {
+	return GetDefaultLogger().SetBacktraceAt(locations...)
+}
+
+// SetBacktraceAllGoroutines controls whether the default logger's
+// SetBacktraceAt dumps include every goroutine, see LogFormatter.
+func SetBacktraceAllGoroutines(all bool) {
+	GetDefaultLogger().SetBacktraceAllGoroutines(all)
+}
+
 // EnableDefaultLoggerForUtility configures the global logger for short-lived
 // command line utilities. It combines console output with a buffered file sink.
 func EnableDefaultLoggerForUtility() {
@@ -101,3 +130,48 @@ func Close() {
 	gDefaultLogger = NewLogger(NopSink{}, false)
 	gDefaultLoggerGuard.Unlock()
 }
+
+// State is an opaque snapshot of the default logger captured by SaveState.
+type State struct {
+	logger Logger
+}
+
+// SaveState captures the currently installed default logger so a test can
+// swap in its own logger and later undo that with RestoreState. The logger
+// is held by reference: its verbosity, vmodule and backtrace configuration
+// is not copied, so changes made to it (e.g. via SetVerbosity, SetVModule or
+// SetBacktraceAt) while it stays installed are not undone by RestoreState.
+// Typical use:
+//
+//	defer rlog.RestoreState(rlog.SaveState())
+//	capture := rlog.NewCaptureSink()
+//	rlog.ReplaceDefaultLogger(rlog.NewLogger(capture, false))
+func SaveState() State {
+	gDefaultLoggerGuard.Lock()
+	s := State{logger: gDefaultLogger}
+	gDefaultLoggerGuard.Unlock()
+	return s
+}
+
+// RestoreState installs the logger captured by a prior SaveState call as the
+// default logger again, closing whatever logger is currently installed.
+func RestoreState(s State) {
+	gDefaultLoggerGuard.Lock()
+	if gDefaultLogger != nil && gDefaultLogger != s.logger {
+		gDefaultLogger.Close()
+	}
+	gDefaultLogger = s.logger
+	gDefaultLoggerGuard.Unlock()
+}
+
+// ReplaceDefaultLogger installs logger as the default logger without closing
+// whatever logger is currently installed, since that logger may still be
+// referenced by a State captured via SaveState. It is primarily useful in
+// tests, paired with SaveState/RestoreState to install a CaptureSink
+// temporarily; the caller is responsible for closing the displaced logger if
+// it isn't going to be restored.
+func ReplaceDefaultLogger(logger Logger) {
+	gDefaultLoggerGuard.Lock()
+	gDefaultLogger = logger
+	gDefaultLoggerGuard.Unlock()
+}
@@ -0,0 +1,76 @@
+package rlog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveStateRestoreStateSwapsDefaultLogger(t *testing.T) {
+	defer RestoreState(SaveState())
+
+	capture := NewCaptureSink()
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	replacement := NewLogger(capture, false)
+	replacement.SetClock(clock)
+	ReplaceDefaultLogger(replacement)
+
+	Infof("hello")
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if !entries[0].When.Equal(clock.now) {
+		t.Errorf("timestamp = %v, want %v", entries[0].When, clock.now)
+	}
+}
+
+func TestRestoreStateRevertsToPriorLogger(t *testing.T) {
+	saved := SaveState()
+
+	capture := NewCaptureSink()
+	ReplaceDefaultLogger(NewLogger(capture, false))
+	Infof("during swap")
+
+	RestoreState(saved)
+	Infof("after restore")
+
+	if entries := capture.Entries(); len(entries) != 1 {
+		t.Errorf("restored logger should not receive further records, got %d entries", len(entries))
+	}
+}
+
+// TestReplaceDefaultLoggerDoesNotCloseSavedLogger guards against
+// ReplaceDefaultLogger closing a logger that's still referenced by a saved
+// State: with a Close-sensitive sink like BufferedSink, closing it out from
+// under a pending RestoreState leaves the pump goroutine gone and any
+// further log call (or Flush) blocked on its queue forever.
+func TestReplaceDefaultLoggerDoesNotCloseSavedLogger(t *testing.T) {
+	orig := SaveState()
+	defer RestoreState(orig)
+
+	capture := NewCaptureSink()
+	buffered := NewBufferedSink(2, capture)
+	ReplaceDefaultLogger(NewLogger(buffered, false))
+
+	saved := SaveState()
+	ReplaceDefaultLogger(NewLogger(NewCaptureSink(), false))
+	RestoreState(saved)
+
+	done := make(chan struct{})
+	go func() {
+		Infof("still alive")
+		GetDefaultLogger().Flush()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Infof after RestoreState hung — the restored logger's BufferedSink must have been closed")
+	}
+
+	if entries := capture.Entries(); len(entries) != 1 {
+		t.Errorf("got %d entries, want 1", len(entries))
+	}
+}
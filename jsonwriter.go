@@ -0,0 +1,124 @@
+package rlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var jsonLevelNames = []string{
+	"DEBUG",
+	"INFO",
+	"AUDIT",
+	"WARN",
+	"ERROR",
+}
+
+// JSONWriter renders log lines as newline-delimited JSON objects, one per
+// record, suitable for ingestion by log aggregators. Writes are serialized
+// since the destination writer is not assumed to be safe for concurrent use.
+type JSONWriter struct {
+	w        io.Writer
+	minLevel int
+	mut      sync.Mutex
+}
+
+// NewJSONWriter creates a structured sink that writes one JSON object per
+// record to w, filtering out records below minLevel.
+func NewJSONWriter(w io.Writer, minLevel int) *JSONWriter {
+	return &JSONWriter{
+		w:        w,
+		minLevel: minLevel,
+	}
+}
+
+func (self *JSONWriter) IsEnabled(level int) bool {
+	return level >= self.minLevel
+}
+
+func (self *JSONWriter) Log(when time.Time, level int, message string) {
+	self.LogKV(when, level, "", message, nil)
+}
+
+// LogKV writes message and fields as a single JSON line, reusing the pooled
+// bytes.Buffer so the fast path avoids an extra allocation per record.
+func (self *JSONWriter) LogKV(when time.Time, level int, caller string, message string, fields []interface{}) {
+	if level < self.minLevel {
+		return
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	encodeJSONLine(buf, when, level, caller, message, fields)
+
+	self.mut.Lock()
+	_, _ = self.w.Write(buf.Bytes())
+	self.mut.Unlock()
+
+	buf.Reset()
+	bufPool.Put(buf)
+}
+
+// encodeJSONLine appends a single newline-delimited JSON record to buf. It
+// is shared by JSONWriter and ConnWriter so both sinks agree on the wire
+// format expected by downstream log collectors.
+func encodeJSONLine(buf *bytes.Buffer, when time.Time, level int, caller string, message string, fields []interface{}) {
+	var name string
+	if level >= 0 && level < len(jsonLevelNames) {
+		name = jsonLevelNames[level]
+	}
+
+	buf.WriteByte('{')
+	writeJSONField(buf, "ts", when.Format(time.RFC3339Nano), true)
+	writeJSONField(buf, "level", name, false)
+	writeJSONField(buf, "msg", message, false)
+	if caller != "" {
+		writeJSONField(buf, "caller", caller, false)
+	}
+	for i := 0; i+1 < len(fields); i += 2 {
+		writeJSONField(buf, keyString(fields[i]), fields[i+1], false)
+	}
+	if len(fields)%2 == 1 {
+		writeJSONField(buf, keyString(fields[len(fields)-1]), "MISSING", false)
+	}
+	buf.WriteString("}\n")
+}
+
+// writeJSONField appends a "key":value pair to buf, prefixing with a comma
+// unless first is set. value is marshaled with encoding/json so arbitrary
+// field types are handled safely.
+func writeJSONField(buf *bytes.Buffer, key string, value interface{}, first bool) {
+	if !first {
+		buf.WriteByte(',')
+	}
+
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprintf("%v", value))
+	}
+	buf.Write(v)
+}
+
+// keyString coerces a field key of any type to a string for use as a JSON
+// object key, mirroring the "%v" rendering used by the plain text sinks.
+func keyString(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", key)
+}
+
+func (self *JSONWriter) Close() {
+}
+
+func (self *JSONWriter) Flush() {
+	if f, ok := self.w.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+}
@@ -0,0 +1,87 @@
+package rlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONWriterEncodesCoreFields(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf, Debug)
+
+	when := time.Date(2026, 7, 28, 12, 0, 0, 0, time.UTC)
+	w.LogKV(when, Warn, "foo.go:10", "disk low", []interface{}{"free_mb", 12})
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v (%q)", err, buf.String())
+	}
+
+	if got["level"] != "WARN" {
+		t.Errorf("level = %v, want WARN", got["level"])
+	}
+	if got["msg"] != "disk low" {
+		t.Errorf("msg = %v, want %q", got["msg"], "disk low")
+	}
+	if got["caller"] != "foo.go:10" {
+		t.Errorf("caller = %v, want foo.go:10", got["caller"])
+	}
+	if got["free_mb"] != float64(12) {
+		t.Errorf("free_mb = %v, want 12", got["free_mb"])
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("}\n")) {
+		t.Errorf("line not newline-terminated: %q", buf.String())
+	}
+}
+
+func TestJSONWriterFiltersBelowMinLevel(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf, Warn)
+
+	w.LogKV(time.Now(), Info, "", "ignored", nil)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing written below minLevel, got %q", buf.String())
+	}
+}
+
+func TestJSONWriterOmitsEmptyCaller(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf, Debug)
+
+	w.LogKV(time.Now(), Info, "", "hello", nil)
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if _, ok := got["caller"]; ok {
+		t.Errorf("caller should be omitted when empty, got %v", got["caller"])
+	}
+}
+
+func TestJSONWriterTrailingOddFieldIsNotSilentlyDropped(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf, Debug)
+
+	w.LogKV(time.Now(), Info, "", "hello", []interface{}{"orphan"})
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v (%q)", err, buf.String())
+	}
+	if v, ok := got["orphan"]; !ok || v != "MISSING" {
+		t.Errorf(`got %v, want a "orphan":"MISSING" field matching the text sink's behavior`, got)
+	}
+}
+
+func TestKeyStringCoercesNonStringKeys(t *testing.T) {
+	if got := keyString(42); got != "42" {
+		t.Errorf("keyString(42) = %q, want %q", got, "42")
+	}
+	if got := keyString("already"); got != "already" {
+		t.Errorf("keyString(%q) = %q, want unchanged", "already", got)
+	}
+}
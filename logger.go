@@ -10,6 +10,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -37,37 +38,112 @@ type Logger interface {
 	Auditf(format string, a ...interface{})
 	Warnf(format string, a ...interface{})
 	Errorf(format string, a ...interface{})
+
+	// With returns a child logger that attaches the given alternating
+	// keys/values to every record it emits, in addition to any inherited
+	// from an ancestor With call.
+	With(keysAndValues ...interface{}) Logger
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Auditw(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+
+	// V reports whether the given verbosity level is enabled for the calling
+	// file/line, honoring any vmodule overrides installed via SetVModule.
+	V(level int) Verbose
+	// SetVerbosity sets the fallback verbosity level used by V.
+	SetVerbosity(level int)
+	// SetVModule installs per-file verbosity overrides, see SetVModule on LogFormatter.
+	SetVModule(spec string) error
+	// SetBacktraceAt installs the "file.go:42" locations whose Errorf calls
+	// should attach a goroutine stack dump, see SetBacktraceAt on LogFormatter.
+	SetBacktraceAt(locations ...string) error
+	// SetBacktraceAllGoroutines controls whether a stack dump captured via
+	// SetBacktraceAt includes every goroutine or only the one that logged.
+	SetBacktraceAllGoroutines(all bool)
+	// SetClock overrides the time source used for record timestamps, letting
+	// tests inject a fake Clock instead of the wall clock.
+	SetClock(clock Clock)
+
+	// Every returns a child logger that admits at most one call per d from
+	// its call site, discarding the rest.
+	Every(d time.Duration) Logger
+	// FirstN returns a child logger that admits only the first n calls from
+	// its call site, discarding the rest.
+	FirstN(n int) Logger
 }
 
-// LogFormatter marshals formatted log messages and forwards them to the sink.
-// It optionally annotates records with caller file/line metadata.
-type LogFormatter struct {
+// formatterCore holds the state shared between a LogFormatter and every child
+// logger derived from it via With, so that they serialize access to the same
+// sink and share the same verbosity configuration.
+type formatterCore struct {
 	dest         LogSink
 	showFileLine bool
 	mut          sync.Mutex
+
+	clock Clock // guarded by mut; defaults to realClock, overridable via SetClock for tests
+
+	verbosity int32        // atomic, fallback level for V when no vmodule entry matches
+	vmodule   atomic.Value // []modulePat
+	vcache    atomic.Value // *sync.Map, PC -> resolved verbosity level
+
+	backtraceAt  atomic.Value // []backtraceLoc, locations installed via SetBacktraceAt
+	btcache      atomic.Value // *sync.Map, PC -> whether the call site matches backtraceAt
+	backtraceAll int32        // atomic bool, whether captureStack dumps every goroutine
+
+	throttle atomic.Value // *sync.Map, PC -> *throttleState, shared by Every/FirstN
+}
+
+// LogFormatter marshals formatted log messages and forwards them to the sink.
+// It optionally annotates records with caller file/line metadata.
+type LogFormatter struct {
+	core   *formatterCore
+	fields []interface{} // baked-in key/value pairs installed via With
 }
 
 // NewLogger constructs a thread-safe Logger around the provided sink.
 // When showFileLine is true the resulting logger prepends caller information
 // to each message, which is handy for troubleshooting utilities.
 func NewLogger(sink LogSink, showFileLine bool) Logger {
-	return &LogFormatter{
+	core := &formatterCore{
 		dest:         sink,
 		showFileLine: showFileLine,
+		clock:        realClock{},
 	}
+	core.vcache.Store(&sync.Map{})
+	core.btcache.Store(&sync.Map{})
+	core.throttle.Store(&sync.Map{})
+	return &LogFormatter{core: core}
 }
 
 func (self *LogFormatter) IsEnabled(level int) bool {
-	self.mut.Lock()
-	r := self.dest.IsEnabled(level)
-	self.mut.Unlock()
+	self.core.mut.Lock()
+	r := self.core.dest.IsEnabled(level)
+	self.core.mut.Unlock()
 	return r
 }
 
 func (self *LogFormatter) Log(when time.Time, level int, message string) {
-	self.mut.Lock()
-	self.dest.Log(when, level, message)
-	self.mut.Unlock()
+	self.core.mut.Lock()
+	self.core.dest.Log(when, level, message)
+	self.core.mut.Unlock()
+}
+
+// SetClock overrides the time source used to stamp records, defaulting to
+// the wall clock. Tests use this to assert on deterministic timestamps.
+func (self *LogFormatter) SetClock(clock Clock) {
+	self.core.mut.Lock()
+	self.core.clock = clock
+	self.core.mut.Unlock()
+}
+
+// now returns the current time as reported by the configured Clock.
+func (self *LogFormatter) now() time.Time {
+	self.core.mut.Lock()
+	clock := self.core.clock
+	self.core.mut.Unlock()
+	return clock.Now()
 }
 
 var bufPool = sync.Pool{
@@ -76,36 +152,145 @@ var bufPool = sync.Pool{
 	},
 }
 
+// wrapperFrame reports whether file belongs to one of the package's own
+// logger-wrapping call sites: the package-level helpers in global.go and the
+// throttledLogger methods in throttle.go. Both introduce an extra stack
+// frame between the logical caller and the LogFormatter method it reached,
+// which callerInfo and appendBacktrace need to skip over.
+func wrapperFrame(file string) bool {
+	switch filepath.Base(file) {
+	case "global.go", "throttle.go":
+		return true
+	default:
+		return false
+	}
+}
+
+// callerInfo resolves the "file:line" of the logical caller of a public
+// Xxxf/Xxxw method, skip frames up from this function. It unwinds any
+// wrapperFrame frames so that routing a call through global.go's
+// package-level helpers or a throttledLogger still attributes it to the
+// original call site.
+func (self *LogFormatter) callerInfo(skip int) string {
+	var file string
+	var line int
+	var ok bool
+	for {
+		_, file, line, ok = runtime.Caller(skip)
+		if !ok || !wrapperFrame(file) {
+			break
+		}
+		skip++
+	}
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+}
+
+// emit hands the rendered buf to the sink, routing through LogKV when dest
+// understands structured fields, and always returns buf to bufPool.
+func (self *LogFormatter) emit(now time.Time, level int, caller string, buf *bytes.Buffer, fields []interface{}) {
+	self.core.mut.Lock()
+	if kv, ok := self.core.dest.(LogSinkKV); ok {
+		kv.LogKV(now, level, caller, buf.String(), fields)
+	} else {
+		self.core.dest.Log(now, level, buf.String())
+	}
+	self.core.mut.Unlock()
+
+	buf.Reset()
+	bufPool.Put(buf)
+}
+
 func (self *LogFormatter) format(level int, format string, a ...interface{}) {
 	if !self.IsEnabled(level) {
 		return
 	}
 
-	now := time.Now()
+	now := self.now()
+
+	var caller string
+	if self.core.showFileLine {
+		caller = self.callerInfo(3)
+	}
+
+	_, isKV := self.core.dest.(LogSinkKV)
 
 	buf := bufPool.Get().(*bytes.Buffer)
-	if self.showFileLine {
-		_, file, line, ok := runtime.Caller(2)
-		if ok {
-			filename := filepath.Base(file)
-			if filename == "global.go" {
-				_, file, line, ok = runtime.Caller(3)
-				if ok {
-					filename = filepath.Base(file)
-				}
-			}
-			_, _ = fmt.Fprintf(buf, "%s:%d: ", filename, line)
-		}
+	if !isKV && caller != "" {
+		_, _ = fmt.Fprintf(buf, "%s: ", caller)
 	}
 
 	_, _ = fmt.Fprintf(buf, format, a...)
 
-	self.mut.Lock()
-	self.dest.Log(now, level, buf.String())
-	self.mut.Unlock()
+	if !isKV {
+		appendFieldsText(buf, self.fields)
+	}
 
-	buf.Reset()
-	bufPool.Put(buf)
+	if level == Error {
+		self.appendBacktrace(buf, 3)
+	}
+
+	self.emit(now, level, caller, buf, self.fields)
+}
+
+// appendBacktrace appends a goroutine stack dump to buf when the caller
+// skip frames up (relative to this function) matches a location installed
+// via SetBacktraceAt. Like callerInfo, it unwinds any wrapperFrame frames
+// first so the resolved location matches the logical call site.
+func (self *LogFormatter) appendBacktrace(buf *bytes.Buffer, skip int) {
+	var pc uintptr
+	var file string
+	var line int
+	var ok bool
+	for {
+		pc, file, line, ok = runtime.Caller(skip)
+		if !ok || !wrapperFrame(file) {
+			break
+		}
+		skip++
+	}
+	if !ok {
+		return
+	}
+
+	if !self.shouldBacktrace(pc, file, line) {
+		return
+	}
+
+	buf.WriteByte('\n')
+	buf.WriteString(captureStack(atomic.LoadInt32(&self.core.backtraceAll) != 0))
+}
+
+func (self *LogFormatter) formatw(level int, msg string, keysAndValues []interface{}) {
+	if !self.IsEnabled(level) {
+		return
+	}
+
+	now := self.now()
+
+	var caller string
+	if self.core.showFileLine {
+		caller = self.callerInfo(3)
+	}
+
+	fields := mergeFields(self.fields, keysAndValues)
+	_, isKV := self.core.dest.(LogSinkKV)
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	if !isKV && caller != "" {
+		_, _ = fmt.Fprintf(buf, "%s: ", caller)
+	}
+
+	buf.WriteString(msg)
+
+	if !isKV {
+		appendFieldsText(buf, fields)
+	}
+
+	self.emit(now, level, caller, buf, fields)
 }
 
 func (self *LogFormatter) Debugf(format string, a ...interface{}) {
@@ -124,20 +309,53 @@ func (self *LogFormatter) Warnf(format string, a ...interface{}) {
 	self.format(Warn, format, a...)
 }
 
+// Errorf logs at Error level and, when the call site matches a location
+// installed via SetBacktraceAt, appends a goroutine stack dump to the
+// record so the caller can be diagnosed without redeploying. Fatalf goes
+// through this same path, since it logs at Error level before exiting.
 func (self *LogFormatter) Errorf(format string, a ...interface{}) {
 	self.format(Error, format, a...)
 }
 
+// With returns a child logger sharing this logger's sink and verbosity
+// configuration, with keysAndValues baked in as fields on every record it emits.
+func (self *LogFormatter) With(keysAndValues ...interface{}) Logger {
+	return &LogFormatter{
+		core:   self.core,
+		fields: mergeFields(self.fields, keysAndValues),
+	}
+}
+
+func (self *LogFormatter) Debugw(msg string, keysAndValues ...interface{}) {
+	self.formatw(Debug, msg, keysAndValues)
+}
+
+func (self *LogFormatter) Infow(msg string, keysAndValues ...interface{}) {
+	self.formatw(Info, msg, keysAndValues)
+}
+
+func (self *LogFormatter) Auditw(msg string, keysAndValues ...interface{}) {
+	self.formatw(Audit, msg, keysAndValues)
+}
+
+func (self *LogFormatter) Warnw(msg string, keysAndValues ...interface{}) {
+	self.formatw(Warn, msg, keysAndValues)
+}
+
+func (self *LogFormatter) Errorw(msg string, keysAndValues ...interface{}) {
+	self.formatw(Error, msg, keysAndValues)
+}
+
 func (self *LogFormatter) Close() {
-	self.mut.Lock()
-	self.dest.Close()
-	self.mut.Unlock()
+	self.core.mut.Lock()
+	self.core.dest.Close()
+	self.core.mut.Unlock()
 }
 
 func (self *LogFormatter) Flush() {
-	self.mut.Lock()
-	self.dest.Flush()
-	self.mut.Unlock()
+	self.core.mut.Lock()
+	self.core.dest.Flush()
+	self.core.mut.Unlock()
 }
 
 // NewDefaultLogToConsole returns a convenience logger that writes to STDOUT/STDERR
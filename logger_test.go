@@ -0,0 +1,39 @@
+package rlog
+
+import "testing"
+
+func TestWithAttachesFieldsToEveryRecord(t *testing.T) {
+	capture := NewCaptureSink()
+	logger := NewLogger(capture, false)
+
+	child := logger.With("request", "abc").With("user", "alice")
+	child.Infof("handled")
+	child.Infow("handled", "status", 200)
+
+	entries := capture.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+
+	want := []interface{}{"request", "abc", "user", "alice"}
+	if !fieldsEqual(entries[0].Fields, want) {
+		t.Errorf("Infof fields = %v, want %v", entries[0].Fields, want)
+	}
+
+	wantw := []interface{}{"request", "abc", "user", "alice", "status", 200}
+	if !fieldsEqual(entries[1].Fields, wantw) {
+		t.Errorf("Infow fields = %v, want %v", entries[1].Fields, wantw)
+	}
+}
+
+func fieldsEqual(got, want []interface{}) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
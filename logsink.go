@@ -1,6 +1,8 @@
 package rlog
 
 import (
+	"bytes"
+	"fmt"
 	"time"
 )
 
@@ -15,6 +17,63 @@ type LogSink interface {
 	Close()
 }
 
+// LogSinkKV is implemented by sinks that understand structured key-value
+// fields directly, rather than having them pre-rendered as "key=value" text
+// appended to message. caller is the "file:line" of the log call, or empty
+// when the logger was not configured to record it.
+type LogSinkKV interface {
+	LogSink
+	LogKV(when time.Time, level int, caller string, message string, fields []interface{})
+}
+
+// appendFieldsText renders fields as alternating "key=value" pairs appended to
+// buf, for sinks that only understand plain text messages.
+func appendFieldsText(buf *bytes.Buffer, fields []interface{}) {
+	for i := 0; i+1 < len(fields); i += 2 {
+		_, _ = fmt.Fprintf(buf, " %v=%v", fields[i], fields[i+1])
+	}
+	if len(fields)%2 == 1 {
+		_, _ = fmt.Fprintf(buf, " %v=MISSING", fields[len(fields)-1])
+	}
+}
+
+// mergeFields concatenates base and extra without mutating either, preserving
+// base's order so later keys can shadow earlier ones when rendered.
+func mergeFields(base, extra []interface{}) []interface{} {
+	if len(extra) == 0 {
+		return base
+	}
+	if len(base) == 0 {
+		return extra
+	}
+
+	merged := make([]interface{}, 0, len(base)+len(extra))
+	merged = append(merged, base...)
+	merged = append(merged, extra...)
+	return merged
+}
+
+// deliverKV forwards a structured record to dest, routing through LogKV when
+// dest supports it, and otherwise rendering caller/fields as plain text.
+func deliverKV(dest LogSink, when time.Time, level int, caller, message string, fields []interface{}) {
+	if kv, ok := dest.(LogSinkKV); ok {
+		kv.LogKV(when, level, caller, message, fields)
+		return
+	}
+
+	buf := bufPool.Get().(*bytes.Buffer)
+	if caller != "" {
+		_, _ = fmt.Fprintf(buf, "%s: ", caller)
+	}
+	buf.WriteString(message)
+	appendFieldsText(buf, fields)
+
+	dest.Log(when, level, buf.String())
+
+	buf.Reset()
+	bufPool.Put(buf)
+}
+
 // NopSink discards every log message and reports all levels as disabled.
 // It is primarily used to keep the global logger in a safe, inert state.
 type NopSink struct{}
@@ -27,6 +27,13 @@ func (self MultiWriter) Log(when time.Time, level int, message string) {
 	self.second.Log(when, level, message)
 }
 
+// LogKV fans a structured record out to both sinks, routing through LogKV on
+// whichever ones understand it so fields survive the trip through MultiWriter.
+func (self MultiWriter) LogKV(when time.Time, level int, caller string, message string, fields []interface{}) {
+	deliverKV(self.first, when, level, caller, message, fields)
+	deliverKV(self.second, when, level, caller, message, fields)
+}
+
 func (self MultiWriter) Close() {
 	self.first.Close()
 	self.second.Close()
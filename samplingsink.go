@@ -0,0 +1,83 @@
+package rlog
+
+import (
+	"sync"
+	"time"
+)
+
+// SamplingSink admits the first `first` messages per level within each tick
+// window, then lets through only 1 in `thereafter` of the remainder,
+// resetting its counts at the start of every window. This protects a slow
+// downstream sink (e.g. a blocking BufferedSink) from being overwhelmed by a
+// hot loop that logs at a steady, unbounded rate.
+type SamplingSink struct {
+	downstream LogSink
+	tick       time.Duration
+	first      int
+	thereafter int
+
+	mut       sync.Mutex
+	windowEnd time.Time
+	counts    map[int]int
+}
+
+// NewSamplingSink wraps downstream so that, per tick window and per level,
+// the first `first` messages are admitted and then only 1 in `thereafter`
+// of the rest.
+func NewSamplingSink(downstream LogSink, tick time.Duration, first, thereafter int) *SamplingSink {
+	return &SamplingSink{
+		downstream: downstream,
+		tick:       tick,
+		first:      first,
+		thereafter: thereafter,
+		counts:     make(map[int]int),
+	}
+}
+
+func (self *SamplingSink) IsEnabled(level int) bool {
+	return self.downstream.IsEnabled(level)
+}
+
+// admit reports whether a message at level should be forwarded, rolling
+// over the per-window counts once the current tick has elapsed.
+func (self *SamplingSink) admit(level int) bool {
+	self.mut.Lock()
+	defer self.mut.Unlock()
+
+	now := time.Now()
+	if !now.Before(self.windowEnd) {
+		self.windowEnd = now.Add(self.tick)
+		self.counts = make(map[int]int)
+	}
+
+	self.counts[level]++
+	n := self.counts[level]
+
+	if n <= self.first {
+		return true
+	}
+	if self.thereafter <= 0 {
+		return false
+	}
+	return (n-self.first)%self.thereafter == 0
+}
+
+func (self *SamplingSink) Log(when time.Time, level int, message string) {
+	if self.admit(level) {
+		self.downstream.Log(when, level, message)
+	}
+}
+
+func (self *SamplingSink) LogKV(when time.Time, level int, caller string, message string, fields []interface{}) {
+	if self.admit(level) {
+		deliverKV(self.downstream, when, level, caller, message, fields)
+	}
+}
+
+func (self *SamplingSink) Close() {
+	self.downstream.Close()
+}
+
+func (self *SamplingSink) Flush() {
+	self.downstream.Flush()
+}
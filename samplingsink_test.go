@@ -0,0 +1,36 @@
+package rlog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSamplingSinkAdmitsFirstThenSamples(t *testing.T) {
+	capture := NewCaptureSink()
+	sink := NewSamplingSink(capture, time.Hour, 2, 3)
+
+	for i := 0; i < 8; i++ {
+		sink.Log(time.Time{}, Info, "msg")
+	}
+
+	entries := capture.Entries()
+	// first=2 admitted outright, then every 3rd of the remaining 6 (the 3rd and 6th of those).
+	if len(entries) != 4 {
+		t.Fatalf("got %d entries, want 4", len(entries))
+	}
+}
+
+func TestSamplingSinkResetsCountsPerWindow(t *testing.T) {
+	capture := NewCaptureSink()
+	sink := NewSamplingSink(capture, -time.Nanosecond, 1, 0)
+
+	// A window that's already elapsed before every call means every call
+	// starts a fresh window, so each one is admitted as the window's first.
+	for i := 0; i < 3; i++ {
+		sink.Log(time.Time{}, Info, "msg")
+	}
+
+	if entries := capture.Entries(); len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+}
@@ -0,0 +1,136 @@
+package rlog
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+// throttleState holds the mutable counters for one call site tracked by
+// Every or FirstN.
+type throttleState struct {
+	mut    sync.Mutex
+	nextAt time.Time // Every: next time admission is allowed
+	seen   int       // FirstN: number of calls admitted so far
+}
+
+// throttleState returns the shared counters for the call site at pc, lazily
+// creating one on first use.
+func (self *LogFormatter) throttleState(pc uintptr) *throttleState {
+	cache, _ := self.core.throttle.Load().(*sync.Map)
+	v, _ := cache.LoadOrStore(pc, &throttleState{})
+	return v.(*throttleState)
+}
+
+// throttledLogger wraps a Logger so that every Debugf/Infof/.../Errorw call
+// first consults admit before delegating, discarding the call when admit
+// returns false.
+type throttledLogger struct {
+	Logger
+	admit func() bool
+}
+
+// Every returns a child logger that admits at most one call per d from its
+// call site, discarding the rest. The call site is the line where Every is
+// invoked, so `logger.Every(time.Second).Infof(...)` inside a hot loop logs
+// at most once a second regardless of how often the loop runs.
+func (self *LogFormatter) Every(d time.Duration) Logger {
+	pc, _, _, _ := runtime.Caller(1)
+	state := self.throttleState(pc)
+
+	return &throttledLogger{
+		Logger: self,
+		admit: func() bool {
+			state.mut.Lock()
+			defer state.mut.Unlock()
+
+			now := self.now()
+			if now.Before(state.nextAt) {
+				return false
+			}
+			state.nextAt = now.Add(d)
+			return true
+		},
+	}
+}
+
+// FirstN returns a child logger that admits only the first n calls from its
+// call site, discarding the rest.
+func (self *LogFormatter) FirstN(n int) Logger {
+	pc, _, _, _ := runtime.Caller(1)
+	state := self.throttleState(pc)
+
+	return &throttledLogger{
+		Logger: self,
+		admit: func() bool {
+			state.mut.Lock()
+			defer state.mut.Unlock()
+
+			if state.seen >= n {
+				return false
+			}
+			state.seen++
+			return true
+		},
+	}
+}
+
+func (self *throttledLogger) Debugf(format string, a ...interface{}) {
+	if self.admit() {
+		self.Logger.Debugf(format, a...)
+	}
+}
+
+func (self *throttledLogger) Infof(format string, a ...interface{}) {
+	if self.admit() {
+		self.Logger.Infof(format, a...)
+	}
+}
+
+func (self *throttledLogger) Auditf(format string, a ...interface{}) {
+	if self.admit() {
+		self.Logger.Auditf(format, a...)
+	}
+}
+
+func (self *throttledLogger) Warnf(format string, a ...interface{}) {
+	if self.admit() {
+		self.Logger.Warnf(format, a...)
+	}
+}
+
+func (self *throttledLogger) Errorf(format string, a ...interface{}) {
+	if self.admit() {
+		self.Logger.Errorf(format, a...)
+	}
+}
+
+func (self *throttledLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	if self.admit() {
+		self.Logger.Debugw(msg, keysAndValues...)
+	}
+}
+
+func (self *throttledLogger) Infow(msg string, keysAndValues ...interface{}) {
+	if self.admit() {
+		self.Logger.Infow(msg, keysAndValues...)
+	}
+}
+
+func (self *throttledLogger) Auditw(msg string, keysAndValues ...interface{}) {
+	if self.admit() {
+		self.Logger.Auditw(msg, keysAndValues...)
+	}
+}
+
+func (self *throttledLogger) Warnw(msg string, keysAndValues ...interface{}) {
+	if self.admit() {
+		self.Logger.Warnw(msg, keysAndValues...)
+	}
+}
+
+func (self *throttledLogger) Errorw(msg string, keysAndValues ...interface{}) {
+	if self.admit() {
+		self.Logger.Errorw(msg, keysAndValues...)
+	}
+}
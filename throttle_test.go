@@ -0,0 +1,39 @@
+package rlog
+
+import "testing"
+
+func TestThrottledLoggerPreservesCallerInfo(t *testing.T) {
+	capture := NewCaptureSink()
+	logger := NewLogger(capture, true)
+
+	logger.Every(0).Errorf("boom")
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+
+	const wantCaller = "throttle_test.go:9"
+	if entries[0].Caller != wantCaller {
+		t.Errorf("caller = %q, want %q", entries[0].Caller, wantCaller)
+	}
+}
+
+func TestThrottledLoggerHonorsBacktraceAt(t *testing.T) {
+	capture := NewCaptureSink()
+	logger := NewLogger(capture, true)
+
+	if err := logger.SetBacktraceAt("throttle_test.go:30"); err != nil {
+		t.Fatalf("SetBacktraceAt: %v", err)
+	}
+
+	logger.Every(0).Errorf("boom")
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	if entries[0].Message == "boom" {
+		t.Errorf("message has no backtrace appended: %q", entries[0].Message)
+	}
+}
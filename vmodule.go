@@ -0,0 +1,181 @@
+package rlog
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Verbose is returned by Logger.V and gates Debugf/Infof calls on whether the
+// call site's verbosity threshold was met. A disabled Verbose is a cheap,
+// allocation-free no-op.
+type Verbose struct {
+	enabled bool
+	logger  *LogFormatter
+}
+
+// Enabled reports whether the requested verbosity level was met at the call site.
+func (self Verbose) Enabled() bool {
+	return self.enabled
+}
+
+// Debugf logs at Debug level when the Verbose is enabled.
+func (self Verbose) Debugf(format string, a ...interface{}) {
+	if !self.enabled {
+		return
+	}
+	self.logger.format(Debug, format, a...)
+}
+
+// Infof logs at Info level when the Verbose is enabled.
+func (self Verbose) Infof(format string, a ...interface{}) {
+	if !self.enabled {
+		return
+	}
+	self.logger.format(Info, format, a...)
+}
+
+// modulePat is one parsed entry of a vmodule spec, mapping a glob pattern
+// against a source file to a verbosity level.
+type modulePat struct {
+	pattern string
+	literal bool // pattern has no '*' or '?' and can be compared with ==
+	level   int
+}
+
+// parseVModule parses a comma-separated "file1=2,pkg/*=3" spec into a table
+// of modulePat entries.
+func parseVModule(spec string) ([]modulePat, error) {
+	if spec == "" {
+		return nil, nil
+	}
+
+	entries := strings.Split(spec, ",")
+	table := make([]modulePat, 0, len(entries))
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("rlog: invalid vmodule entry %q", entry)
+		}
+
+		pattern := strings.TrimSpace(kv[0])
+		level, err := strconv.Atoi(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("rlog: invalid vmodule level in %q: %w", entry, err)
+		}
+
+		table = append(table, modulePat{
+			pattern: pattern,
+			literal: !strings.ContainsAny(pattern, "*?"),
+			level:   level,
+		})
+	}
+
+	return table, nil
+}
+
+// matches reports whether file (as returned by runtime.Caller) satisfies m.
+// Patterns containing '/' are matched against the trailing slash-separated
+// segments of the full path, otherwise against the base filename with its
+// ".go" suffix stripped.
+func (m modulePat) matches(file string) bool {
+	if !strings.Contains(m.pattern, "/") {
+		subject := strings.TrimSuffix(filepath.Base(file), ".go")
+		if m.literal {
+			return subject == m.pattern
+		}
+		matched, _ := filepath.Match(m.pattern, subject)
+		return matched
+	}
+
+	subject := strings.TrimSuffix(filepath.ToSlash(file), ".go")
+	return matchPathSuffix(m.pattern, subject)
+}
+
+// matchPathSuffix reports whether pattern matches the trailing segments of
+// the slash-separated subject, comparing one segment at a time with
+// filepath.Match so that '*'/'?' never cross a '/' boundary. This lets a
+// pattern like "pkg/*" match a real multi-segment absolute path such as
+// "/home/user/project/pkg/foo.go" without needing to spell out every
+// leading directory.
+func matchPathSuffix(pattern, subject string) bool {
+	patSegs := strings.Split(pattern, "/")
+	subSegs := strings.Split(subject, "/")
+	if len(patSegs) > len(subSegs) {
+		return false
+	}
+
+	tail := subSegs[len(subSegs)-len(patSegs):]
+	for i, p := range patSegs {
+		if matched, _ := filepath.Match(p, tail[i]); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// SetVerbosity sets the fallback verbosity level used by V when no vmodule
+// entry matches the caller's file, and invalidates the per-call-site cache.
+func (self *LogFormatter) SetVerbosity(level int) {
+	atomic.StoreInt32(&self.core.verbosity, int32(level))
+	self.core.vcache.Store(&sync.Map{})
+}
+
+// SetVModule parses a comma-separated "file1=2,pkg/*=3" spec and atomically
+// installs it, invalidating the per-call-site verbosity cache built by V.
+func (self *LogFormatter) SetVModule(spec string) error {
+	table, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+
+	self.core.vmodule.Store(table)
+	self.core.vcache.Store(&sync.Map{})
+	return nil
+}
+
+// resolveVerbosity looks up the verbosity threshold configured for file,
+// falling back to the global verbosity when no vmodule entry matches.
+func (self *LogFormatter) resolveVerbosity(file string) int {
+	table, _ := self.core.vmodule.Load().([]modulePat)
+	for _, m := range table {
+		if m.matches(file) {
+			return m.level
+		}
+	}
+	return int(atomic.LoadInt32(&self.core.verbosity))
+}
+
+// V reports whether level is enabled for the calling file/line and returns a
+// Verbose that Debugf/Infof calls can be chained off of. The resolved
+// threshold is cached per call site (keyed by program counter) so repeated
+// calls from the same line are cheap.
+func (self *LogFormatter) V(level int) Verbose {
+	pc, file, _, ok := runtime.Caller(1)
+	if ok && filepath.Base(file) == "global.go" {
+		pc, file, _, ok = runtime.Caller(2)
+	}
+	if !ok {
+		return Verbose{enabled: level <= int(atomic.LoadInt32(&self.core.verbosity)), logger: self}
+	}
+
+	cache, _ := self.core.vcache.Load().(*sync.Map)
+
+	threshold, found := cache.Load(pc)
+	if !found {
+		threshold = self.resolveVerbosity(file)
+		cache.Store(pc, threshold)
+	}
+
+	return Verbose{enabled: level <= threshold.(int), logger: self}
+}
@@ -0,0 +1,51 @@
+package rlog
+
+import "testing"
+
+func TestModulePatMatchesBaseName(t *testing.T) {
+	table, err := parseVModule("vmodule_test=3,other*=2")
+	if err != nil {
+		t.Fatalf("parseVModule: %v", err)
+	}
+
+	if !table[0].matches("/some/path/vmodule_test.go") {
+		t.Errorf("literal pattern did not match by base name")
+	}
+	if !table[1].matches("/some/path/otherfile.go") {
+		t.Errorf("glob pattern did not match by base name")
+	}
+	if table[1].matches("/some/path/vmodule_test.go") {
+		t.Errorf("glob pattern matched an unrelated file")
+	}
+}
+
+func TestModulePatMatchesFullPathAcrossSegments(t *testing.T) {
+	table, err := parseVModule("rlogcheck/*=3")
+	if err != nil {
+		t.Fatalf("parseVModule: %v", err)
+	}
+
+	if !table[0].matches("/tmp/rlogcheck/x.go") {
+		t.Errorf("path pattern did not match a real multi-segment absolute path")
+	}
+	if table[0].matches("/tmp/other/x.go") {
+		t.Errorf("path pattern matched a file outside its directory")
+	}
+}
+
+func TestVHonorsVModuleOverride(t *testing.T) {
+	capture := NewCaptureSink()
+	logger := NewLogger(capture, false)
+	logger.SetVerbosity(0)
+
+	if err := logger.SetVModule("vmodule_test=2"); err != nil {
+		t.Fatalf("SetVModule: %v", err)
+	}
+
+	logger.V(2).Infof("shown")
+
+	entries := capture.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1 (vmodule override should have enabled V(2))", len(entries))
+	}
+}